@@ -0,0 +1,153 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+package yptr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/zillow/go-yaml/v3"
+)
+
+// CompileDotted translates a dotted-path expression such as
+// "spec.template.containers[0].image" or "spec.containers[name=app].image"
+// into the equivalent extended JSON pointer ("/spec/template/containers/0/image",
+// "/spec/containers/~[name=app]/image") understood by Find, Insert and Remove.
+//
+// Bracket indexing accepts an integer, "-" (append), or a "key=value" /
+// "key=\"quoted value\"" predicate, compiled to a ~[key=value] token. Dots
+// inside a key can be escaped with a backslash ("a\.b") or by quoting the
+// whole key in brackets (["a.b"]).
+func CompileDotted(expr string) (string, error) {
+	toks, err := dottedToTokens(expr)
+	if err != nil {
+		return "", fmt.Errorf("%q: %w", expr, err)
+	}
+	if len(toks) == 0 {
+		return emptyJSONPointer, nil
+	}
+
+	var b strings.Builder
+	for _, t := range toks {
+		b.WriteByte('/')
+		b.WriteString(t)
+	}
+	return b.String(), nil
+}
+
+// FindDotted is like Find but accepts a dotted-path expression; see CompileDotted.
+func FindDotted(root *yaml.Node, expr string) (*yaml.Node, error) {
+	ptr, err := CompileDotted(expr)
+	if err != nil {
+		return nil, err
+	}
+	return Find(root, ptr)
+}
+
+// InsertDotted is like Insert but accepts a dotted-path expression; see CompileDotted.
+func InsertDotted(root *yaml.Node, expr string, value yaml.Node) error {
+	ptr, err := CompileDotted(expr)
+	if err != nil {
+		return err
+	}
+	return Insert(root, ptr, value)
+}
+
+// RemoveDotted is like Remove but accepts a dotted-path expression; see CompileDotted.
+func RemoveDotted(root *yaml.Node, expr string) error {
+	ptr, err := CompileDotted(expr)
+	if err != nil {
+		return err
+	}
+	return Remove(root, ptr)
+}
+
+// dottedToTokens parses expr into a sequence of already pointer-escaped
+// tokens (literal keys are RFC 6901-escaped; indices, "-" and ~[...]
+// predicates are emitted verbatim).
+func dottedToTokens(expr string) ([]string, error) {
+	var toks []string
+	var cur strings.Builder
+
+	flushKey := func() {
+		if cur.Len() > 0 {
+			toks = append(toks, escapeToken(cur.String()))
+			cur.Reset()
+		}
+	}
+
+	for i := 0; i < len(expr); {
+		switch c := expr[i]; c {
+		case '.':
+			flushKey()
+			i++
+		case '\\':
+			if i+1 >= len(expr) {
+				return nil, fmt.Errorf(`dangling "\" escape`)
+			}
+			cur.WriteByte(expr[i+1])
+			i += 2
+		case '[':
+			flushKey()
+			end := findMatchingBracket(expr, i)
+			if end < 0 {
+				return nil, fmt.Errorf(`unterminated "["`)
+			}
+			tok, err := compileBracket(expr[i+1 : end])
+			if err != nil {
+				return nil, err
+			}
+			toks = append(toks, tok)
+			i = end + 1
+		default:
+			cur.WriteByte(c)
+			i++
+		}
+	}
+	flushKey()
+	return toks, nil
+}
+
+// findMatchingBracket returns the index of the "]" matching the "[" at
+// expr[open], respecting double-quoted substrings, or -1 if there is none.
+func findMatchingBracket(expr string, open int) int {
+	inQuotes := false
+	for i := open + 1; i < len(expr); i++ {
+		switch expr[i] {
+		case '"':
+			inQuotes = !inQuotes
+		case ']':
+			if !inQuotes {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// compileBracket translates the contents of a single [...] segment into a
+// pointer token.
+func compileBracket(inner string) (string, error) {
+	if inner == "-" {
+		return "-", nil
+	}
+	if n, err := strconv.Atoi(inner); err == nil && n >= 0 {
+		return inner, nil
+	}
+	if len(inner) >= 2 && inner[0] == '"' && inner[len(inner)-1] == '"' && !strings.ContainsRune(inner[1:len(inner)-1], '=') {
+		key, err := strconv.Unquote(inner)
+		if err != nil {
+			return "", err
+		}
+		return escapeToken(key), nil
+	}
+	if strings.ContainsRune(inner, '=') {
+		if _, err := parseKVClauses(inner); err != nil {
+			return "", err
+		}
+		return "~[" + inner + "]", nil
+	}
+	return "", fmt.Errorf("invalid bracket expression %q", inner)
+}
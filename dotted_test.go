@@ -0,0 +1,89 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+package yptr_test
+
+import (
+	"fmt"
+	"testing"
+
+	yptr "github.com/zillow/go-yaml-jsonpointer"
+	"github.com/zillow/go-yaml/v3"
+)
+
+func ExampleFindDotted() {
+	src := `
+spec:
+  template:
+    containers:
+    - name: app
+      image: nginx
+`
+	var n yaml.Node
+	yaml.Unmarshal([]byte(src), &n)
+
+	r, _ := yptr.FindDotted(&n, `spec.template.containers[name=app].image`)
+	fmt.Println(r.Value)
+	// Output: nginx
+}
+
+func TestCompileDotted(t *testing.T) {
+	testCases := []struct {
+		expr string
+		ptr  string
+	}{
+		{`spec.template.containers[0].image`, `/spec/template/containers/0/image`},
+		{`spec.containers[name=app].image`, `/spec/containers/~[name=app]/image`},
+		{`spec.containers[name="my app"].image`, `/spec/containers/~[name="my app"]/image`},
+		{`spec.containers[-]`, `/spec/containers/-`},
+		{`a\.b.c`, `/a.b/c`},
+		{`a["x.y"].c`, `/a/x.y/c`},
+		{``, ``},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.expr, func(t *testing.T) {
+			got, err := yptr.CompileDotted(tc.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.ptr {
+				t.Fatalf("got: %q, want: %q", got, tc.ptr)
+			}
+		})
+	}
+}
+
+func TestInsertRemoveDotted(t *testing.T) {
+	src := `
+spec:
+  containers:
+  - name: app
+    image: nginx
+`
+	var n yaml.Node
+	if err := yaml.Unmarshal([]byte(src), &n); err != nil {
+		t.Fatal(err)
+	}
+
+	var tag yaml.Node
+	if err := yaml.Unmarshal([]byte(`v2`), &tag); err != nil {
+		t.Fatal(err)
+	}
+	if err := yptr.InsertDotted(&n, `spec.containers[name=app].tag`, tag); err != nil {
+		t.Fatal(err)
+	}
+	r, err := yptr.FindDotted(&n, `spec.containers[name=app].tag`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := r.Value, "v2"; got != want {
+		t.Fatalf("got: %v, want: %v", got, want)
+	}
+
+	if err := yptr.RemoveDotted(&n, `spec.containers[name=app].tag`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := yptr.FindDotted(&n, `spec.containers[name=app].tag`); err == nil {
+		t.Fatal("expecting removed field to be gone")
+	}
+}
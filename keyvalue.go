@@ -0,0 +1,125 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+package yptr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/zillow/go-yaml/v3"
+)
+
+// kvClause is a single "key=value" clause of a ~[...] predicate. path holds
+// the dotted key split into its components, so ~[metadata.name=foo] walks
+// metadata then name.
+type kvClause struct {
+	path  []string
+	value string
+}
+
+// keyValuePred parses a ~[key=value] or ~[key1=value1,key2=value2] token
+// (values may be quoted, e.g. ~[name="my app"]) and returns a nodePredicate
+// that matches sequence items whose scalar field(s) equal the given
+// value(s).
+func keyValuePred(tok string) (nodePredicate, error) {
+	if !strings.HasPrefix(tok, "~[") || !strings.HasSuffix(tok, "]") {
+		return nil, fmt.Errorf("invalid key=value predicate %q", tok)
+	}
+
+	clauses, err := parseKVClauses(tok[2 : len(tok)-1])
+	if err != nil {
+		return nil, fmt.Errorf("%q: %w", tok, err)
+	}
+
+	return func(n *yaml.Node) bool {
+		for _, c := range clauses {
+			v, ok := lookupScalarPath(n, c.path)
+			if !ok || v != c.value {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+func parseKVClauses(s string) ([]kvClause, error) {
+	parts, err := splitUnquoted(s, ',')
+	if err != nil {
+		return nil, err
+	}
+
+	clauses := make([]kvClause, 0, len(parts))
+	for _, p := range parts {
+		eq := strings.IndexByte(p, '=')
+		if eq < 0 {
+			return nil, fmt.Errorf("missing \"=\" in clause %q", p)
+		}
+		val, err := unquoteIfQuoted(p[eq+1:])
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, kvClause{path: strings.Split(p[:eq], "."), value: val})
+	}
+	return clauses, nil
+}
+
+// splitUnquoted splits s on sep, except when sep occurs inside a
+// double-quoted substring.
+func splitUnquoted(s string, sep byte) ([]string, error) {
+	var parts []string
+	var cur strings.Builder
+	inQuotes := false
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"':
+			inQuotes = !inQuotes
+			cur.WriteByte(c)
+		case c == sep && !inQuotes:
+			parts = append(parts, cur.String())
+			cur.Reset()
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote in %q", s)
+	}
+	parts = append(parts, cur.String())
+	return parts, nil
+}
+
+func unquoteIfQuoted(v string) (string, error) {
+	if len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"' {
+		return strconv.Unquote(v)
+	}
+	return v, nil
+}
+
+// lookupScalarPath walks n, a mapping node, following path and returns the
+// scalar value found at the end of it.
+func lookupScalarPath(n *yaml.Node, path []string) (string, bool) {
+	cur := n
+	for _, key := range path {
+		if cur.Kind != yaml.MappingNode {
+			return "", false
+		}
+		found := false
+		for i := 0; i < len(cur.Content); i += 2 {
+			if cur.Content[i].Value == key {
+				cur = cur.Content[i+1]
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", false
+		}
+	}
+	if cur.Kind != yaml.ScalarNode {
+		return "", false
+	}
+	return cur.Value, true
+}
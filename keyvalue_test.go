@@ -0,0 +1,70 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+package yptr_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	yptr "github.com/zillow/go-yaml-jsonpointer"
+	"github.com/zillow/go-yaml/v3"
+)
+
+func ExampleFind_keyValuePredicate() {
+	src := `
+spec:
+  containers:
+  - name: app
+    image: nginx
+  - name: sidecar
+    image: mysidecar
+`
+	var n yaml.Node
+	yaml.Unmarshal([]byte(src), &n)
+
+	r, _ := yptr.Find(&n, `/spec/containers/~[name=app]/image`)
+	fmt.Println(r.Value)
+	// Output: nginx
+}
+
+func TestKeyValuePredicate(t *testing.T) {
+	src := `
+apps:
+- metadata:
+    name: foo
+  kind: web
+- metadata:
+    name: "my app"
+  kind: batch
+`
+	var n yaml.Node
+	if err := yaml.Unmarshal([]byte(src), &n); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		ptr   string
+		value string
+	}{
+		{`/apps/~[metadata.name=foo]/kind`, "web"},
+		{`/apps/~[metadata.name="my app"]/kind`, "batch"},
+		{`/apps/~[metadata.name=foo,kind=web]/kind`, "web"},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			r, err := yptr.Find(&n, tc.ptr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := r.Value, tc.value; got != want {
+				t.Fatalf("got: %v, want: %v", got, want)
+			}
+		})
+	}
+
+	if _, err := yptr.Find(&n, `/apps/~[metadata.name=missing]/kind`); !errors.Is(err, yptr.ErrNotFound) {
+		t.Fatalf("expecting not found error, got: %v", err)
+	}
+}
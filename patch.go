@@ -0,0 +1,313 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+package yptr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/zillow/go-yaml/v3"
+)
+
+// PatchOp is a single RFC 6902 JSON Patch operation. Value is decoded from
+// JSON, so it may be a string, float64, bool, nil, []interface{} or
+// map[string]interface{}, matching the shape produced by json.Unmarshal.
+type PatchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	From  string      `json:"from,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Patch applies an RFC 6902 JSON Patch document to the yaml tree rooted at
+// root, one operation at a time, in order. Paths may use the same extended
+// JSON pointer syntax (including `~{...}` and `~[key=value]` filters)
+// accepted by Find and Insert.
+//
+// "add" delegates to Insert when the target path doesn't yet exist, and
+// replaces the existing node otherwise. "replace" fails with ErrNotFound if
+// the target is missing. "move" and "copy" are implemented in terms of
+// Remove/Insert and Find/Insert respectively. "test" performs a structural
+// comparison that ignores style and comments.
+func Patch(root *yaml.Node, ops []PatchOp) error {
+	if root.Kind == yaml.DocumentNode {
+		root = root.Content[0]
+	}
+
+	for i, op := range ops {
+		if err := applyOp(root, op); err != nil {
+			return fmt.Errorf("patch op %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	return nil
+}
+
+func applyOp(root *yaml.Node, op PatchOp) error {
+	switch op.Op {
+	case "add":
+		return applyAdd(root, op.Path, op.Value)
+	case "remove":
+		return Remove(root, op.Path)
+	case "replace":
+		return applyReplace(root, op.Path, op.Value)
+	case "move":
+		return applyMove(root, op.From, op.Path)
+	case "copy":
+		return applyCopy(root, op.From, op.Path)
+	case "test":
+		return applyTest(root, op.Path, op.Value)
+	default:
+		return fmt.Errorf("unknown patch operation %q", op.Op)
+	}
+}
+
+func applyAdd(root *yaml.Node, path string, raw interface{}) error {
+	value, err := toYAMLNode(raw)
+	if err != nil {
+		return err
+	}
+	return addNode(root, path, value)
+}
+
+// addNode inserts value at path, or replaces whatever is already there if
+// the path already resolves to a node.
+func addNode(root *yaml.Node, path string, value yaml.Node) error {
+	if _, err := Find(root, path); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return Insert(root, path, value)
+		}
+		return err
+	}
+	return Replace(root, path, value)
+}
+
+func applyReplace(root *yaml.Node, path string, raw interface{}) error {
+	value, err := toYAMLNode(raw)
+	if err != nil {
+		return err
+	}
+	return Replace(root, path, value)
+}
+
+func applyMove(root *yaml.Node, from, path string) error {
+	if from == path {
+		return nil
+	}
+	if strings.HasPrefix(path, from+jsonPointerSeparator) {
+		return fmt.Errorf("cannot move %q into its own descendant %q", from, path)
+	}
+
+	value, err := Find(root, from)
+	if err != nil {
+		return err
+	}
+	moved := *value
+	if err := Remove(root, from); err != nil {
+		return err
+	}
+	return addNode(root, path, moved)
+}
+
+func applyCopy(root *yaml.Node, from, path string) error {
+	value, err := Find(root, from)
+	if err != nil {
+		return err
+	}
+	return addNode(root, path, *deepCopyNode(value))
+}
+
+func applyTest(root *yaml.Node, path string, raw interface{}) error {
+	value, err := toYAMLNode(raw)
+	if err != nil {
+		return err
+	}
+	target, err := Find(root, path)
+	if err != nil {
+		return err
+	}
+	if !nodesEqual(target, &value) {
+		return fmt.Errorf("test failed: %q does not equal expected value", path)
+	}
+	return nil
+}
+
+// Replace overwrites the node located at ptr with value, in place, failing
+// with ErrNotFound if ptr doesn't resolve to an existing node. Unlike
+// Insert, Replace mutates the target *yaml.Node directly so that any
+// anchors/aliases referencing it continue to resolve to the new content.
+func Replace(root *yaml.Node, ptr string, value yaml.Node) error {
+	if value.Kind == yaml.DocumentNode {
+		value = *value.Content[0]
+	}
+
+	target, err := Find(root, ptr)
+	if err != nil {
+		return err
+	}
+	target.Kind = value.Kind
+	target.Tag = value.Tag
+	target.Value = value.Value
+	target.Content = value.Content
+	target.Style = value.Style
+	return nil
+}
+
+// Remove deletes the map key/value pair or sequence element located at ptr.
+// "-" is rejected since it doesn't reference an existing element.
+func Remove(root *yaml.Node, ptr string) error {
+	toks, err := jsonPointerToTokens(ptr)
+	if err != nil {
+		return err
+	}
+	if root.Kind == yaml.DocumentNode {
+		root = root.Content[0]
+	}
+	if len(toks) == 0 {
+		return fmt.Errorf("cannot remove root node")
+	}
+
+	parent := root
+	if len(toks) > 1 {
+		parents, err := find(root, toks[:len(toks)-1])
+		if err != nil {
+			return fmt.Errorf("%q: %w", ptr, err)
+		}
+		if len(parents) != 1 {
+			return fmt.Errorf("got %d matches: %w", len(parents), ErrTooManyResults)
+		}
+		parent = parents[0]
+	}
+
+	tok := toks[len(toks)-1]
+	if err := removeChild(parent, tok); err != nil {
+		return fmt.Errorf("%q: %w", ptr, err)
+	}
+	return nil
+}
+
+func removeChild(parent *yaml.Node, tok string) error {
+	switch parent.Kind {
+	case yaml.MappingNode:
+		for i := 0; i < len(parent.Content); i += 2 {
+			if parent.Content[i].Value == tok {
+				parent.Content = slices.Delete(parent.Content, i, i+2)
+				return nil
+			}
+		}
+		return fmt.Errorf("%q: %w", tok, ErrNotFound)
+	case yaml.SequenceNode:
+		if tok == "-" {
+			return fmt.Errorf("%q does not reference an existing element", tok)
+		}
+		matches, err := match(parent, tok)
+		if err != nil {
+			return err
+		}
+		if len(matches) != 1 {
+			return fmt.Errorf("got %d matches: %w", len(matches), ErrTooManyResults)
+		}
+		for i, n := range parent.Content {
+			if n == matches[0] {
+				parent.Content = slices.Delete(parent.Content, i, i+1)
+				return nil
+			}
+		}
+		return fmt.Errorf("%q: %w", tok, ErrNotFound)
+	default:
+		return fmt.Errorf("unhandled node type: %v (%v)", parent.Kind, parent.Tag)
+	}
+}
+
+// toYAMLNode converts a json.Unmarshal-shaped value (as found in a PatchOp's
+// Value field) into a yaml.Node tree by round-tripping it through JSON,
+// which go-yaml parses as a strict subset of YAML.
+func toYAMLNode(raw interface{}) (yaml.Node, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return yaml.Node{}, err
+	}
+	var n yaml.Node
+	if err := yaml.Unmarshal(data, &n); err != nil {
+		return yaml.Node{}, err
+	}
+	if n.Kind == yaml.DocumentNode {
+		n = *n.Content[0]
+	}
+	clearStyle(&n)
+	return n, nil
+}
+
+// clearStyle recursively zeroes n's Style, so a node produced by
+// round-tripping through JSON (which yaml.Unmarshal renders with
+// JSON-literal styles like double-quoted scalars and flow-style
+// collections) takes on the document's default plain/block style instead.
+func clearStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		clearStyle(c)
+	}
+}
+
+// deepCopyNode returns a copy of n with its own Content slice, recursively,
+// so mutating the copy (or its children) never affects n.
+func deepCopyNode(n *yaml.Node) *yaml.Node {
+	cp := *n
+	if n.Content != nil {
+		cp.Content = make([]*yaml.Node, len(n.Content))
+		for i, c := range n.Content {
+			cp.Content[i] = deepCopyNode(c)
+		}
+	}
+	return &cp
+}
+
+// nodesEqual reports whether a and b are structurally equal, ignoring
+// style, comments, anchors and source position.
+func nodesEqual(a, b *yaml.Node) bool {
+	if a.Kind == yaml.DocumentNode {
+		return nodesEqual(a.Content[0], b)
+	}
+	if b.Kind == yaml.DocumentNode {
+		return nodesEqual(a, b.Content[0])
+	}
+	if a.Kind != b.Kind {
+		return false
+	}
+
+	switch a.Kind {
+	case yaml.ScalarNode:
+		return a.Tag == b.Tag && a.Value == b.Value
+	case yaml.SequenceNode:
+		if len(a.Content) != len(b.Content) {
+			return false
+		}
+		for i := range a.Content {
+			if !nodesEqual(a.Content[i], b.Content[i]) {
+				return false
+			}
+		}
+		return true
+	case yaml.MappingNode:
+		if len(a.Content) != len(b.Content) {
+			return false
+		}
+		bv := make(map[string]*yaml.Node, len(b.Content)/2)
+		for i := 0; i < len(b.Content); i += 2 {
+			bv[b.Content[i].Value] = b.Content[i+1]
+		}
+		for i := 0; i < len(a.Content); i += 2 {
+			v, ok := bv[a.Content[i].Value]
+			if !ok || !nodesEqual(a.Content[i+1], v) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+package yptr_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	yptr "github.com/zillow/go-yaml-jsonpointer"
+	"github.com/zillow/go-yaml/v3"
+)
+
+func ExamplePatch() {
+	src := `
+spec:
+  replicas: 1
+  containers:
+  - name: app
+    image: nginx
+  - name: sidecar
+    image: mysidecar
+`
+	var n yaml.Node
+	yaml.Unmarshal([]byte(src), &n)
+
+	ops := []yptr.PatchOp{
+		{Op: "replace", Path: `/spec/replicas`, Value: 3},
+		{Op: "add", Path: `/spec/containers/~{"name":"app"}/image`, Value: "nginx:1.2"},
+		{Op: "remove", Path: `/spec/containers/~{"name":"sidecar"}`},
+		{Op: "add", Path: `/spec/labels`, Value: map[string]interface{}{"team": "infra"}},
+	}
+
+	if err := yptr.Patch(&n, ops); err != nil {
+		panic(err)
+	}
+
+	out, err := yaml.Marshal(n.Content[0])
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(out))
+	// Output:
+	// spec:
+	//     replicas: 3
+	//     containers:
+	//         - name: app
+	//           image: nginx:1.2
+	//     labels:
+	//         team: infra
+}
+
+func TestPatchErrors(t *testing.T) {
+	src := `
+spec:
+  replicas: 1
+  containers:
+  - name: app
+    image: nginx
+`
+	tests := []struct {
+		name string
+		op   yptr.PatchOp
+		err  error
+	}{
+		{"replace missing", yptr.PatchOp{Op: "replace", Path: `/spec/missing`, Value: 1}, yptr.ErrNotFound},
+		{"remove dash", yptr.PatchOp{Op: "remove", Path: `/spec/containers/-`}, nil},
+		{"unknown op", yptr.PatchOp{Op: "frobnicate", Path: `/spec/replicas`}, nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var n yaml.Node
+			yaml.Unmarshal([]byte(src), &n)
+
+			err := yptr.Patch(&n, []yptr.PatchOp{tc.op})
+			if err == nil {
+				t.Fatal("expecting error")
+			}
+			if tc.err != nil && !errors.Is(err, tc.err) {
+				t.Fatalf("expecting error %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestPatchTest(t *testing.T) {
+	src := `
+spec:
+  replicas: 1
+`
+	var n yaml.Node
+	yaml.Unmarshal([]byte(src), &n)
+
+	if err := yptr.Patch(&n, []yptr.PatchOp{{Op: "test", Path: `/spec/replicas`, Value: 1}}); err != nil {
+		t.Fatalf("expecting test to pass: %v", err)
+	}
+	if err := yptr.Patch(&n, []yptr.PatchOp{{Op: "test", Path: `/spec/replicas`, Value: 2}}); err == nil {
+		t.Fatal("expecting test to fail")
+	}
+}
+
+func TestPatchTestDistinguishesType(t *testing.T) {
+	src := `
+spec:
+  replicas: 1
+`
+	var n yaml.Node
+	yaml.Unmarshal([]byte(src), &n)
+
+	// The YAML int 1 and the JSON string "1" aren't structurally equal,
+	// even though they share the same textual Value.
+	if err := yptr.Patch(&n, []yptr.PatchOp{{Op: "test", Path: `/spec/replicas`, Value: "1"}}); err == nil {
+		t.Fatal("expecting test to fail: int 1 is not the string \"1\"")
+	}
+}
+
+func TestMoveRejectsDescendant(t *testing.T) {
+	src := `
+a:
+  b:
+    c: 1
+`
+	var n yaml.Node
+	yaml.Unmarshal([]byte(src), &n)
+
+	err := yptr.Patch(&n, []yptr.PatchOp{{Op: "move", Path: `/a/b`, From: `/a`}})
+	if err == nil {
+		t.Fatal("expecting error")
+	}
+}
+
+func TestPatchAppend(t *testing.T) {
+	src := `
+items:
+- a
+- b
+`
+
+	t.Run("add", func(t *testing.T) {
+		var n yaml.Node
+		yaml.Unmarshal([]byte(src), &n)
+
+		if err := yptr.Patch(&n, []yptr.PatchOp{{Op: "add", Path: `/items/-`, Value: "c"}}); err != nil {
+			t.Fatal(err)
+		}
+		items, err := yptr.FindAll(&n, `/items/*`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != 3 {
+			t.Fatalf("expecting 3 items, got %d", len(items))
+		}
+		if got, want := items[2].Value, "c"; got != want {
+			t.Fatalf("got: %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("copy", func(t *testing.T) {
+		var n yaml.Node
+		yaml.Unmarshal([]byte(src), &n)
+
+		if err := yptr.Patch(&n, []yptr.PatchOp{{Op: "copy", From: `/items/0`, Path: `/items/-`}}); err != nil {
+			t.Fatal(err)
+		}
+		items, err := yptr.FindAll(&n, `/items/*`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != 3 {
+			t.Fatalf("expecting 3 items, got %d", len(items))
+		}
+		if got, want := items[2].Value, "a"; got != want {
+			t.Fatalf("got: %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("move", func(t *testing.T) {
+		var n yaml.Node
+		yaml.Unmarshal([]byte(src), &n)
+
+		if err := yptr.Patch(&n, []yptr.PatchOp{{Op: "move", From: `/items/0`, Path: `/items/-`}}); err != nil {
+			t.Fatal(err)
+		}
+		items, err := yptr.FindAll(&n, `/items/*`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != 2 {
+			t.Fatalf("expecting 2 items, got %d", len(items))
+		}
+		if got, want := items[0].Value, "b"; got != want {
+			t.Fatalf("got: %v, want: %v", got, want)
+		}
+		if got, want := items[1].Value, "a"; got != want {
+			t.Fatalf("got: %v, want: %v", got, want)
+		}
+	})
+}
+
+func TestReplaceRejectsAppendMarker(t *testing.T) {
+	src := `items: [a, b]`
+	var n yaml.Node
+	yaml.Unmarshal([]byte(src), &n)
+
+	var v yaml.Node
+	yaml.Unmarshal([]byte(`c`), &v)
+	err := yptr.Replace(&n, `/items/-`, *v.Content[0])
+	if !errors.Is(err, yptr.ErrNotFound) {
+		t.Fatalf("expecting ErrNotFound, got: %v", err)
+	}
+}
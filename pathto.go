@@ -0,0 +1,149 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+package yptr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/zillow/go-yaml/v3"
+)
+
+// PathOption configures PathTo.
+type PathOption func(*pathOptions)
+
+type pathOptions struct {
+	identityKeys []string
+}
+
+// WithIdentityKeys makes PathTo prefer a ~[key=value] token over a numeric
+// index for a sequence item that's a mapping containing one of keys (tried
+// in order) as a scalar field, as long as that field's value is unique
+// among the sequence's items. This keeps the resulting pointer stable
+// across reorderings of the sequence.
+func WithIdentityKeys(keys ...string) PathOption {
+	return func(o *pathOptions) { o.identityKeys = keys }
+}
+
+// PathTo returns the extended JSON pointer that locates target within root,
+// the inverse of Find. It returns ErrNotFound if target isn't reachable
+// from root, and ErrTooManyResults if WithIdentityKeys is configured and
+// some sequence item along the way has identity keys whose values are all
+// shared with other items of that sequence.
+func PathTo(root, target *yaml.Node, opts ...PathOption) (string, error) {
+	var o pathOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if root.Kind == yaml.DocumentNode {
+		root = root.Content[0]
+	}
+	if target.Kind == yaml.DocumentNode {
+		target = target.Content[0]
+	}
+	if root == target {
+		return emptyJSONPointer, nil
+	}
+
+	toks, found, err := findPath(root, target, &o)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", ErrNotFound
+	}
+
+	var b strings.Builder
+	for _, t := range toks {
+		b.WriteByte('/')
+		b.WriteString(t)
+	}
+	return b.String(), nil
+}
+
+// findPath searches n for target by pointer identity, returning the tokens
+// of the path from n to target.
+func findPath(n, target *yaml.Node, o *pathOptions) ([]string, bool, error) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i < len(n.Content); i += 2 {
+			key, val := n.Content[i], n.Content[i+1]
+			if val == target {
+				return []string{escapeToken(key.Value)}, true, nil
+			}
+			sub, ok, err := findPath(val, target, o)
+			if err != nil {
+				return nil, false, err
+			}
+			if ok {
+				return append([]string{escapeToken(key.Value)}, sub...), true, nil
+			}
+		}
+	case yaml.SequenceNode:
+		for i, item := range n.Content {
+			if item == target {
+				tok, err := identityToken(n, i, o)
+				if err != nil {
+					return nil, false, err
+				}
+				return []string{tok}, true, nil
+			}
+			sub, ok, err := findPath(item, target, o)
+			if err != nil {
+				return nil, false, err
+			}
+			if ok {
+				tok, err := identityToken(n, i, o)
+				if err != nil {
+					return nil, false, err
+				}
+				return append([]string{tok}, sub...), true, nil
+			}
+		}
+	}
+	return nil, false, nil
+}
+
+// identityToken returns the pointer token for item seq.Content[i]: a
+// ~[key=value] predicate for the first identity key that uniquely
+// identifies it, or its numeric index if it has none of the identity keys.
+// If it has some of them but each one's value is shared with another item,
+// it returns ErrTooManyResults.
+func identityToken(seq *yaml.Node, i int, o *pathOptions) (string, error) {
+	item := seq.Content[i]
+	if item.Kind == yaml.MappingNode {
+		ambiguous := false
+		for _, key := range o.identityKeys {
+			val, ok := lookupScalarPath(item, []string{key})
+			if !ok {
+				continue
+			}
+
+			matches := 0
+			for _, other := range seq.Content {
+				if v, ok := lookupScalarPath(other, []string{key}); ok && v == val {
+					matches++
+				}
+			}
+			if matches > 1 {
+				ambiguous = true
+				continue
+			}
+			return fmt.Sprintf("~[%s=%s]", key, quoteIfNeeded(val)), nil
+		}
+		if ambiguous {
+			return "", fmt.Errorf("all identity keys are ambiguous for item %d: %w", i, ErrTooManyResults)
+		}
+	}
+	return strconv.Itoa(i), nil
+}
+
+func quoteIfNeeded(v string) string {
+	if strings.ContainsAny(v, ` ,="`) {
+		return strconv.Quote(v)
+	}
+	return v
+}
@@ -0,0 +1,101 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+package yptr_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	yptr "github.com/zillow/go-yaml-jsonpointer"
+	"github.com/zillow/go-yaml/v3"
+)
+
+func ExamplePathTo() {
+	src := `
+spec:
+  containers:
+  - name: app
+    image: nginx
+  - name: sidecar
+    image: mysidecar
+`
+	var n yaml.Node
+	yaml.Unmarshal([]byte(src), &n)
+
+	target, _ := yptr.Find(&n, `/spec/containers/1/image`)
+
+	ptr, _ := yptr.PathTo(&n, target)
+	fmt.Println(ptr)
+
+	ptr, _ = yptr.PathTo(&n, target, yptr.WithIdentityKeys("name"))
+	fmt.Println(ptr)
+
+	// Output: /spec/containers/1/image
+	// /spec/containers/~[name=sidecar]/image
+}
+
+func TestPathToErrors(t *testing.T) {
+	src := `
+spec:
+  containers:
+  - name: app
+    image: nginx
+  - name: app
+    image: other
+`
+	var n yaml.Node
+	if err := yaml.Unmarshal([]byte(src), &n); err != nil {
+		t.Fatal(err)
+	}
+
+	target, err := yptr.Find(&n, `/spec/containers/1/image`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := yptr.PathTo(&n, target, yptr.WithIdentityKeys("name")); !errors.Is(err, yptr.ErrTooManyResults) {
+		t.Fatalf("expecting ErrTooManyResults, got: %v", err)
+	}
+
+	var stray yaml.Node
+	if err := yaml.Unmarshal([]byte(`x`), &stray); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := yptr.PathTo(&n, stray.Content[0]); !errors.Is(err, yptr.ErrNotFound) {
+		t.Fatalf("expecting ErrNotFound, got: %v", err)
+	}
+}
+
+func TestPathToIdentityKeyFallback(t *testing.T) {
+	src := `
+spec:
+  containers:
+  - name: app
+    id: 1
+    image: nginx
+  - name: app
+    id: 2
+    image: other
+`
+	var n yaml.Node
+	if err := yaml.Unmarshal([]byte(src), &n); err != nil {
+		t.Fatal(err)
+	}
+
+	target, err := yptr.Find(&n, `/spec/containers/1/image`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// "name" is ambiguous between the two items, so PathTo should fall
+	// through to "id", which uniquely identifies the target.
+	ptr, err := yptr.PathTo(&n, target, yptr.WithIdentityKeys("name", "id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ptr, `/spec/containers/~[id=2]/image`; got != want {
+		t.Fatalf("got: %q, want: %q", got, want)
+	}
+}
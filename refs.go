@@ -0,0 +1,285 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+package yptr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zillow/go-yaml/v3"
+)
+
+// ErrCycle indicates that resolving a $ref would re-enter a node that is
+// already being resolved, preventing the traversal from making progress.
+var ErrCycle = fmt.Errorf("cycle detected while resolving $ref")
+
+// RefLoader loads the document located at uri. It's used to resolve $ref
+// values that point outside of the document being traversed, e.g.
+// "other.yaml#/components/schemas/Foo".
+type RefLoader interface {
+	Load(uri string) (*yaml.Node, error)
+}
+
+// CycleInfo describes a $ref left unresolved by ResolveRefs because
+// following it would re-enter a node already being resolved.
+type CycleInfo struct {
+	// Pointer is the extended JSON pointer of the $ref node.
+	Pointer string
+	// Target is the unresolved $ref value.
+	Target string
+}
+
+// RefOption configures FindAllWithRefs and FindWithRefs.
+type RefOption func(*refResolver)
+
+// WithLoader sets the RefLoader used to resolve $ref values that point
+// outside of the current document. Without a loader, only local ("#/...")
+// refs can be resolved.
+func WithLoader(loader RefLoader) RefOption {
+	return func(r *refResolver) { r.loader = loader }
+}
+
+type refResolver struct {
+	loader RefLoader
+	docs   map[string]*yaml.Node
+	root   *yaml.Node
+
+	// visiting backs resolve(), the traversal used by FindAllWithRefs and
+	// FindWithRefs.
+	visiting map[*yaml.Node]bool
+
+	// onStack and resolved back resolveNode(), the traversal used by
+	// ResolveRefs. onStack tracks nodes currently being resolved on this
+	// call stack (for cycle detection); resolved memoizes nodes that have
+	// already been fully resolved, keyed by node identity, so a node
+	// reachable through more than one path is only resolved once.
+	onStack  map[*yaml.Node]bool
+	resolved map[*yaml.Node]bool
+}
+
+func newRefResolver(root *yaml.Node, opts []RefOption) *refResolver {
+	r := &refResolver{
+		docs:     map[string]*yaml.Node{"": root},
+		root:     root,
+		visiting: map[*yaml.Node]bool{},
+		onStack:  map[*yaml.Node]bool{},
+		resolved: map[*yaml.Node]bool{},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// isRefNode reports whether n is a $ref node (a mapping with a single
+// "$ref" key) and, if so, returns its target URI.
+func isRefNode(n *yaml.Node) (string, bool) {
+	if n.Kind != yaml.MappingNode || len(n.Content) != 2 {
+		return "", false
+	}
+	if n.Content[0].Value != "$ref" || n.Content[1].Kind != yaml.ScalarNode {
+		return "", false
+	}
+	return n.Content[1].Value, true
+}
+
+// resolve follows n's $ref chain to its target. If mustDescend is false and
+// a cycle is hit, the (still unresolved) $ref node n is returned instead of
+// an error, since the caller doesn't need to descend any further.
+func (r *refResolver) resolve(n *yaml.Node, mustDescend bool) (*yaml.Node, error) {
+	uri, ok := isRefNode(n)
+	if !ok {
+		return n, nil
+	}
+	if r.visiting[n] {
+		if mustDescend {
+			return nil, ErrCycle
+		}
+		return n, nil
+	}
+
+	r.visiting[n] = true
+	defer delete(r.visiting, n)
+
+	target, err := r.load(uri)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %q: %w", uri, err)
+	}
+	return r.resolve(target, mustDescend)
+}
+
+// load resolves uri to a node, fetching and caching external documents
+// through the configured RefLoader as needed.
+func (r *refResolver) load(uri string) (*yaml.Node, error) {
+	file, ptr, hasHash := strings.Cut(uri, "#")
+	if !hasHash {
+		file, ptr = uri, ""
+	}
+
+	doc, ok := r.docs[file]
+	if !ok {
+		if r.loader == nil {
+			return nil, fmt.Errorf("no RefLoader configured to load %q", file)
+		}
+		loaded, err := r.loader.Load(file)
+		if err != nil {
+			return nil, err
+		}
+		if loaded.Kind == yaml.DocumentNode {
+			loaded = loaded.Content[0]
+		}
+		r.docs[file] = loaded
+		doc = loaded
+	}
+
+	if ptr == "" {
+		return doc, nil
+	}
+	return Find(doc, ptr)
+}
+
+// FindAllWithRefs is like FindAll, but transparently follows $ref nodes
+// (OpenAPI/JSON-Schema style mappings of the form {"$ref": "..."}) that are
+// encountered while walking ptr, including at intermediate path segments.
+// Cycles encountered while making progress through the pointer return
+// ErrCycle; a $ref cycle reached only at the end of the pointer is returned
+// unresolved instead of erroring. Resolved nodes keep their original
+// Line/Column.
+func FindAllWithRefs(root *yaml.Node, ptr string, opts ...RefOption) ([]*yaml.Node, error) {
+	if root.Kind == yaml.DocumentNode {
+		root = root.Content[0]
+	}
+	toks, err := jsonPointerToTokens(ptr)
+	if err != nil {
+		return nil, err
+	}
+
+	r := newRefResolver(root, opts)
+	cur := []*yaml.Node{root}
+	for _, tok := range toks {
+		var next []*yaml.Node
+		for _, n := range cur {
+			resolved, err := r.resolve(n, true)
+			if err != nil {
+				return nil, fmt.Errorf("%q: %w", ptr, err)
+			}
+			m, err := match(resolved, tok)
+			if err != nil {
+				return nil, fmt.Errorf("%q: %w", ptr, err)
+			}
+			next = append(next, m...)
+		}
+		cur = next
+	}
+
+	res := make([]*yaml.Node, len(cur))
+	for i, n := range cur {
+		resolved, err := r.resolve(n, false)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", ptr, err)
+		}
+		res[i] = resolved
+	}
+	return res, nil
+}
+
+// FindWithRefs is like FindAllWithRefs but returns ErrTooManyResults if
+// multiple matches are located.
+func FindWithRefs(root *yaml.Node, ptr string, opts ...RefOption) (*yaml.Node, error) {
+	res, err := FindAllWithRefs(root, ptr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) > 1 {
+		return nil, fmt.Errorf("got %d matches: %w", len(res), ErrTooManyResults)
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("%q: %w", ptr, ErrNotFound)
+	}
+	return res[0], nil
+}
+
+// ResolveRefs walks the whole tree rooted at root and rewrites every $ref
+// mapping in place with its resolved target, preserving the $ref node's
+// original Line/Column. Cycles are broken by leaving the offending $ref
+// node intact; each one is reported in the returned []CycleInfo together
+// with the extended JSON pointer of the $ref node.
+func ResolveRefs(root *yaml.Node, loader RefLoader) ([]CycleInfo, error) {
+	if root.Kind == yaml.DocumentNode {
+		root = root.Content[0]
+	}
+
+	r := newRefResolver(root, []RefOption{WithLoader(loader)})
+	var cycles []CycleInfo
+	if _, err := r.resolveNode(root, &cycles); err != nil {
+		return cycles, err
+	}
+	return cycles, nil
+}
+
+// resolveNode resolves n in place and returns n. $ref nodes are rewritten
+// in place with their (recursively resolved) target, preserving the $ref
+// node's original Line/Column. A node is only ever resolved once: once n
+// has been fully resolved, the result is memoized by node identity in
+// r.resolved, so a node reachable through more than one path (e.g. two
+// sibling $refs pointing at each other) isn't revisited or duplicated.
+// Re-entering a node that's still being resolved on the current call stack
+// is a cycle: it's recorded in cycles, keyed by the $ref node's own
+// pointer (computed on demand via PathTo), and the $ref node is left
+// unresolved instead of recursing forever.
+func (r *refResolver) resolveNode(n *yaml.Node, cycles *[]CycleInfo) (*yaml.Node, error) {
+	if r.resolved[n] {
+		return n, nil
+	}
+	if r.onStack[n] {
+		return nil, ErrCycle
+	}
+	r.onStack[n] = true
+	defer delete(r.onStack, n)
+
+	if uri, ok := isRefNode(n); ok {
+		target, err := r.load(uri)
+		if err != nil {
+			return nil, fmt.Errorf("resolving %q: %w", uri, err)
+		}
+
+		resolvedTarget, err := r.resolveNode(target, cycles)
+		if errors.Is(err, ErrCycle) {
+			pointer, perr := PathTo(r.root, n)
+			if perr != nil {
+				return nil, perr
+			}
+			*cycles = append(*cycles, CycleInfo{Pointer: pointer, Target: uri})
+			r.resolved[n] = true
+			return n, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, col := n.Line, n.Column
+		*n = *deepCopyNode(resolvedTarget)
+		n.Line, n.Column = line, col
+		r.resolved[n] = true
+		return n, nil
+	}
+
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i < len(n.Content); i += 2 {
+			if _, err := r.resolveNode(n.Content[i+1], cycles); err != nil {
+				return nil, err
+			}
+		}
+	case yaml.SequenceNode:
+		for _, c := range n.Content {
+			if _, err := r.resolveNode(c, cycles); err != nil {
+				return nil, err
+			}
+		}
+	}
+	r.resolved[n] = true
+	return n, nil
+}
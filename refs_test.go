@@ -0,0 +1,222 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+package yptr_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	yptr "github.com/zillow/go-yaml-jsonpointer"
+	"github.com/zillow/go-yaml/v3"
+)
+
+func ExampleFindAllWithRefs() {
+	src := `
+components:
+  schemas:
+    Foo:
+      type: object
+      properties:
+        bar:
+          $ref: "#/components/schemas/Bar"
+    Bar:
+      type: string
+`
+	var n yaml.Node
+	yaml.Unmarshal([]byte(src), &n)
+
+	r, err := yptr.FindWithRefs(&n, `/components/schemas/Foo/properties/bar/type`)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(r.Value)
+	// Output: string
+}
+
+func TestFindWithRefsCycle(t *testing.T) {
+	src := `
+components:
+  schemas:
+    A:
+      $ref: "#/components/schemas/B"
+    B:
+      $ref: "#/components/schemas/A"
+`
+	var n yaml.Node
+	if err := yaml.Unmarshal([]byte(src), &n); err != nil {
+		t.Fatal(err)
+	}
+
+	// Asking for a field past the ref chain forces the resolver to keep
+	// descending through the cycle, which can never make progress.
+	_, err := yptr.FindWithRefs(&n, `/components/schemas/A/type`)
+	if !errors.Is(err, yptr.ErrCycle) {
+		t.Fatalf("expecting ErrCycle, got: %v", err)
+	}
+
+	// Asking for the ref node itself doesn't need to descend any further,
+	// so the cycle is left as an unresolved $ref mapping instead of erroring.
+	r, err := yptr.FindWithRefs(&n, `/components/schemas/A`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Kind != yaml.MappingNode {
+		t.Fatalf("expecting unresolved $ref mapping, got kind %v", r.Kind)
+	}
+}
+
+type mapLoader map[string]string
+
+func (m mapLoader) Load(uri string) (*yaml.Node, error) {
+	src, ok := m[uri]
+	if !ok {
+		return nil, fmt.Errorf("no such document %q", uri)
+	}
+	var n yaml.Node
+	if err := yaml.Unmarshal([]byte(src), &n); err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+func TestResolveRefsExternal(t *testing.T) {
+	src := `
+schemas:
+  Foo:
+    $ref: "other.yaml#/Bar"
+`
+	loader := mapLoader{"other.yaml": "Bar:\n  type: string\n"}
+
+	var n yaml.Node
+	if err := yaml.Unmarshal([]byte(src), &n); err != nil {
+		t.Fatal(err)
+	}
+
+	cycles, err := yptr.ResolveRefs(&n, loader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cycles) != 0 {
+		t.Fatalf("expecting no cycles, got: %v", cycles)
+	}
+
+	r, err := yptr.Find(&n, `/schemas/Foo/type`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := r.Value, "string"; got != want {
+		t.Fatalf("got: %v, want: %v", got, want)
+	}
+}
+
+func TestResolveRefsCycle(t *testing.T) {
+	src := `
+schemas:
+  Foo:
+    self:
+      $ref: "#/schemas/Foo"
+`
+	var n yaml.Node
+	if err := yaml.Unmarshal([]byte(src), &n); err != nil {
+		t.Fatal(err)
+	}
+
+	cycles, err := yptr.ResolveRefs(&n, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cycles) != 1 {
+		t.Fatalf("expecting 1 cycle, got: %v", cycles)
+	}
+	if got, want := cycles[0].Pointer, "/schemas/Foo/self"; got != want {
+		t.Fatalf("got: %v, want: %v", got, want)
+	}
+}
+
+func TestResolveRefsSiblingCycle(t *testing.T) {
+	src := `
+schemas:
+  A:
+    $ref: "#/schemas/B"
+  B:
+    $ref: "#/schemas/A"
+`
+	var n yaml.Node
+	if err := yaml.Unmarshal([]byte(src), &n); err != nil {
+		t.Fatal(err)
+	}
+
+	cycles, err := yptr.ResolveRefs(&n, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cycles) != 1 {
+		t.Fatalf("expecting 1 cycle, got: %v", cycles)
+	}
+
+	a, err := yptr.Find(&n, `/schemas/A`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Kind != yaml.MappingNode {
+		t.Fatalf("expecting A to remain an unresolved $ref mapping, got kind %v", a.Kind)
+	}
+
+	b, err := yptr.Find(&n, `/schemas/B`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Kind != yaml.MappingNode {
+		t.Fatalf("expecting B to remain an unresolved $ref mapping, got kind %v", b.Kind)
+	}
+}
+
+func TestResolveRefsNestedSiblingCycle(t *testing.T) {
+	src := `
+schemas:
+  A:
+    properties:
+      b:
+        $ref: "#/schemas/B"
+  B:
+    properties:
+      a:
+        $ref: "#/schemas/A"
+`
+	var n yaml.Node
+	if err := yaml.Unmarshal([]byte(src), &n); err != nil {
+		t.Fatal(err)
+	}
+
+	cycles, err := yptr.ResolveRefs(&n, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cycles) != 1 {
+		t.Fatalf("expecting 1 cycle, got: %v", cycles)
+	}
+
+	// B must be left exactly as it was: a single unresolved $ref, not
+	// further expanded by A resolving through it.
+	bRef, err := yptr.Find(&n, `/schemas/B/properties/a`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bRef.Kind != yaml.MappingNode {
+		t.Fatalf("expecting B's ref to remain unresolved, got kind %v", bRef.Kind)
+	}
+
+	// A embeds B's (unresolved) shape exactly once, not an ever-growing chain.
+	embedded, err := yptr.Find(&n, `/schemas/A/properties/b/properties/a`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if embedded.Kind != yaml.MappingNode {
+		t.Fatalf("expecting one level of embedding, got kind %v", embedded.Kind)
+	}
+	if _, err := yptr.Find(&n, `/schemas/A/properties/b/properties/a/properties`); err == nil {
+		t.Fatal("expecting embedding to stop after one level, but it kept going")
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2020 VMware, Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+package yptr_test
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+
+	yptr "github.com/zillow/go-yaml-jsonpointer"
+	"github.com/zillow/go-yaml/v3"
+)
+
+func ExampleFindAll_wildcard() {
+	src := `
+items:
+- name: a
+- name: b
+- name: c
+`
+	var n yaml.Node
+	yaml.Unmarshal([]byte(src), &n)
+
+	res, _ := yptr.FindAll(&n, `/items/*/name`)
+	for _, r := range res {
+		fmt.Println(r.Value)
+	}
+	// Output: a
+	// b
+	// c
+}
+
+func ExampleFindAll_recursiveDescent() {
+	src := `
+spec:
+  template:
+    containers:
+    - name: app
+      image: nginx
+    - name: sidecar
+      image: mysidecar
+  selector:
+    image: ignored
+`
+	var n yaml.Node
+	yaml.Unmarshal([]byte(src), &n)
+
+	res, _ := yptr.FindAll(&n, `/spec/**/image`)
+	values := make([]string, len(res))
+	for i, r := range res {
+		values[i] = r.Value
+	}
+	sort.Strings(values)
+	for _, v := range values {
+		fmt.Println(v)
+	}
+	// Output: ignored
+	// mysidecar
+	// nginx
+}
+
+func TestWildcardErrors(t *testing.T) {
+	src := `items: []`
+	var n yaml.Node
+	if err := yaml.Unmarshal([]byte(src), &n); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := yptr.FindAll(&n, `/items/*`); !errors.Is(err, yptr.ErrNotFound) {
+		t.Fatalf("expecting not found error, got: %v", err)
+	}
+}
+
+func TestLiteralAsteriskKey(t *testing.T) {
+	src := "\"*\": x\n"
+	var n yaml.Node
+	if err := yaml.Unmarshal([]byte(src), &n); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := yptr.Find(&n, `/~2`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := r.Value, "x"; got != want {
+		t.Fatalf("got: %v, want: %v", got, want)
+	}
+}
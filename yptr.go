@@ -60,7 +60,21 @@ func Find(root *yaml.Node, ptr string) (*yaml.Node, error) {
 }
 
 // find recursively matches a token against a yaml node.
+//
+// A token can fan out to more than one node (e.g. "*", "~{...}" and
+// "~[key=value]"), in which case the remaining tokens are matched against
+// every branch independently; ErrNotFound is only returned if it's hit on
+// every branch, not just some of them.
 func find(root *yaml.Node, toks []string) ([]*yaml.Node, error) {
+	if toks[0] == "**" {
+		return findRecursive(root, toks[1:])
+	}
+	if toks[0] == "-" {
+		// "-" is an append marker for Insert, not a reference to an
+		// existing element: it never resolves to a node.
+		return nil, fmt.Errorf("%q: %w", toks[0], ErrNotFound)
+	}
+
 	next, err := match(root, toks[0])
 	if err != nil {
 		return nil, err
@@ -69,17 +83,60 @@ func find(root *yaml.Node, toks []string) ([]*yaml.Node, error) {
 		return next, nil
 	}
 
+	return findAllBranches(next, toks[1:])
+}
+
+// findAllBranches matches toks against each of nodes independently,
+// collecting the successful branches. It only returns an error if every
+// branch failed.
+func findAllBranches(nodes []*yaml.Node, toks []string) ([]*yaml.Node, error) {
 	var res []*yaml.Node
-	for _, n := range next {
-		f, err := find(n, toks[1:])
+	var lastErr error
+	for _, n := range nodes {
+		f, err := find(n, toks)
 		if err != nil {
-			return nil, err
+			lastErr = err
+			continue
 		}
 		res = append(res, f...)
 	}
+	if len(res) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
 	return res, nil
 }
 
+// findRecursive implements the "**" recursive-descent token: it matches
+// root plus all of its descendants, then matches toks (which may be empty)
+// against each of them independently.
+func findRecursive(root *yaml.Node, toks []string) ([]*yaml.Node, error) {
+	candidates := collectDescendants(root)
+	if len(toks) == 0 {
+		return candidates, nil
+	}
+
+	res, err := findAllBranches(candidates, toks)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("**: %w", ErrNotFound)
+	}
+	return res, nil
+}
+
+// collectDescendants returns n and all of its descendants, in document order.
+func collectDescendants(n *yaml.Node) []*yaml.Node {
+	if n.Kind == yaml.DocumentNode {
+		return collectDescendants(n.Content[0])
+	}
+	res := []*yaml.Node{n}
+	for _, c := range n.Content {
+		res = append(res, collectDescendants(c)...)
+	}
+	return res
+}
+
 // Insert inserts a value at the location pointed by the JSONPointer ptr in the yaml tree rooted at root.
 // If any nodes along the way do not exist, they are created such that a subsequent call to Find would find
 // the value at that location.
@@ -204,7 +261,17 @@ func mapInsert(root *yaml.Node, toks []string, value yaml.Node) error {
 // If tok is ~{...}, it will parse the {...} object as a JSON object
 // and use it to filter the array using a treeSubsetPred.
 // If tok is ~[key=value] it will use keyValuePred to filter the array.
+//
+// "*" is a wildcard matching every value of a map or every element of an
+// array. A literal "*" key is addressed by escaping it as "~2".
 func match(root *yaml.Node, tok string) ([]*yaml.Node, error) {
+	switch tok {
+	case "*":
+		return matchWildcard(root)
+	case "~2":
+		tok = "*"
+	}
+
 	c := root.Content
 	switch root.Kind {
 	case yaml.MappingNode:
@@ -226,6 +293,19 @@ func match(root *yaml.Node, tok string) ([]*yaml.Node, error) {
 				return nil, err
 			}
 			return filter(c, treeSubsetPred(&mtree))
+		case strings.HasPrefix(tok, "~["): // key=value match: ~[name=app]
+			pred, err := keyValuePred(tok)
+			if err != nil {
+				return nil, err
+			}
+			res, err := filter(c, pred)
+			if err != nil {
+				return nil, err
+			}
+			if len(res) == 0 {
+				return nil, fmt.Errorf("%q: %w", tok, ErrNotFound)
+			}
+			return res, nil
 		default:
 			if tok == "-" {
 				// dummy leaf node
@@ -249,6 +329,32 @@ func match(root *yaml.Node, tok string) ([]*yaml.Node, error) {
 	return nil, fmt.Errorf("%q: %w", tok, ErrNotFound)
 }
 
+// matchWildcard implements the "*" token: every value of a map, or every
+// element of an array.
+func matchWildcard(root *yaml.Node) ([]*yaml.Node, error) {
+	switch root.Kind {
+	case yaml.MappingNode:
+		c := root.Content
+		res := make([]*yaml.Node, 0, len(c)/2)
+		for i := 1; i < len(c); i += 2 {
+			res = append(res, c[i])
+		}
+		if len(res) == 0 {
+			return nil, fmt.Errorf("*: %w", ErrNotFound)
+		}
+		return res, nil
+	case yaml.SequenceNode:
+		if len(root.Content) == 0 {
+			return nil, fmt.Errorf("*: %w", ErrNotFound)
+		}
+		return root.Content, nil
+	case yaml.DocumentNode:
+		return matchWildcard(root.Content[0])
+	default:
+		return nil, fmt.Errorf("*: %w", ErrNotFound)
+	}
+}
+
 type nodePredicate func(*yaml.Node) bool
 
 // filter applies a nodePredicate to each input node and returns only those for which the predicate
@@ -270,6 +376,59 @@ func treeSubsetPred(a *yaml.Node) nodePredicate {
 	}
 }
 
+// isTreeSubset reports whether a is a subset of b: every scalar in a
+// equals the corresponding scalar in b, every key of a mapping a is
+// present in mapping b with a subset value, and, for sequences, a and b
+// have the same length and each element of a is a subset of the
+// corresponding element of b. Document nodes are unwrapped before
+// comparing.
+func isTreeSubset(a, b *yaml.Node) bool {
+	if a.Kind == yaml.DocumentNode {
+		return isTreeSubset(a.Content[0], b)
+	}
+	if b.Kind == yaml.DocumentNode {
+		return isTreeSubset(a, b.Content[0])
+	}
+	if a.Kind != b.Kind {
+		return false
+	}
+
+	switch a.Kind {
+	case yaml.ScalarNode:
+		return a.Value == b.Value
+	case yaml.MappingNode:
+		for i := 0; i < len(a.Content); i += 2 {
+			key, val := a.Content[i], a.Content[i+1]
+			found := false
+			for j := 0; j < len(b.Content); j += 2 {
+				if b.Content[j].Value == key.Value {
+					if !isTreeSubset(val, b.Content[j+1]) {
+						return false
+					}
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	case yaml.SequenceNode:
+		if len(a.Content) != len(b.Content) {
+			return false
+		}
+		for i := range a.Content {
+			if !isTreeSubset(a.Content[i], b.Content[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
 // Given a JSON pointer, return its tokens or an error if invalid.
 func jsonPointerToTokens(jsonPointer string) ([]string, error) {
 	if jsonPointer == emptyJSONPointer {
@@ -284,6 +443,14 @@ func jsonPointerToTokens(jsonPointer string) ([]string, error) {
 	return referenceTokens[1:], nil
 }
 
+// escapeToken RFC 6901-escapes the reference-token special characters ("~"
+// and "/") so s can be embedded as a single token in a JSON pointer.
+func escapeToken(s string) string {
+	s = strings.ReplaceAll(s, "~", "~0")
+	s = strings.ReplaceAll(s, "/", "~1")
+	return s
+}
+
 // Given a JSON pointer, return an error if invalid.
 func ValidateJSONPointer(jsonPointer string) error {
 	if jsonPointer == emptyJSONPointer {